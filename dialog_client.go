@@ -0,0 +1,162 @@
+package sipgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// DialogClientSession is the UAC-side counterpart to DialogServerSession: the
+// INVITE for this dialog was sent by us and answered by the remote UAS.
+type DialogClientSession struct {
+	Dialog
+	c *Client
+
+	mu        sync.Mutex
+	localCSeq uint32
+}
+
+// Refer sends a REFER for this dialog (RFC 3515), asking the remote party to
+// place a new call to target. Refer-To is carried as a generic header since
+// the sip package pinned for this baseline has no typed ReferToHeader.
+//
+// Passing replaces attaches a Replaces header (RFC 3891) for attended
+// transfer: replaces must be the (Call-ID, from-tag, to-tag) triple of the
+// dialog being replaced, in that order.
+//
+// On success it returns a Subscription for reading the NOTIFYs the refer
+// target sends back; pass each one to Subscription.ReadNotify as your
+// application's OnNotify handler receives it, having first looked the
+// Subscription up by SubscriptionID (this tree has no DialogClient-level
+// request router to do that matching itself).
+func (s *DialogClientSession) Refer(ctx context.Context, target sip.Uri, replaces ...string) (*Subscription, error) {
+	req := s.Dialog.InviteRequest
+	res := s.Dialog.InviteResponse
+	if res == nil || !res.IsSuccess() {
+		return nil, fmt.Errorf("sipgo: Refer requires a confirmed dialog")
+	}
+
+	s.mu.Lock()
+	s.localCSeq++
+	cseq := s.localCSeq
+	s.mu.Unlock()
+
+	var replacesHDR string
+	if len(replaces) > 0 {
+		if len(replaces) != 3 {
+			return nil, fmt.Errorf("sipgo: replaces requires exactly (Call-ID, from-tag, to-tag), got %d values", len(replaces))
+		}
+		replacesHDR = fmt.Sprintf("%s;from-tag=%s;to-tag=%s", replaces[0], replaces[1], replaces[2])
+	}
+
+	refer := newReferRequestUAC(req, res, cseq, target.String(), replacesHDR)
+
+	if rr := refer.Route(); rr != nil {
+		refer.SetDestination(rr.Address.HostPort())
+	}
+
+	tx, err := s.c.TransactionRequest(ctx, refer)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Terminate()
+
+	select {
+	case resp := <-tx.Responses():
+		if resp.StatusCode != 202 {
+			return nil, ErrDialogResponse{resp}
+		}
+	case <-tx.Done():
+		return nil, tx.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	callid := req.CallID()
+	from := req.From()
+	to := req.To()
+
+	sub := &Subscription{
+		id:       SubscriptionID(callid.Value(), from.Params["tag"], to.Params["tag"], "refer", fmt.Sprintf("%d", cseq)),
+		event:    "refer",
+		c:        s.c,
+		referReq: req,
+		referRes: res,
+		active:   true,
+	}
+
+	return sub, nil
+}
+
+// ReadNotify should be passed a NOTIFY belonging to this Subscription (the
+// application matches it by SubscriptionID before calling this, since this
+// tree has no request router to do that automatically). It answers 200 OK,
+// updates the subscription's active state from Subscription-State, and
+// returns the sipfrag body reporting the referred request's progress.
+func (s *Subscription) ReadNotify(req *sip.Request, tx sip.ServerTransaction) ([]byte, error) {
+	res := sip.NewResponseFromRequest(req, 200, "OK", nil)
+	if err := tx.Respond(res); err != nil {
+		return nil, err
+	}
+
+	if state := req.GetHeader("Subscription-State"); state != nil {
+		s.mu.Lock()
+		s.active = !isTerminatedState(state.Value())
+		s.mu.Unlock()
+	}
+
+	return req.Body(), nil
+}
+
+func isTerminatedState(v string) bool {
+	for i, c := range v {
+		if c == ';' {
+			v = v[:i]
+			break
+		}
+	}
+	return v == "terminated"
+}
+
+// newReferRequestUAC generates a REFER request from the UAC side of an
+// established dialog. Unlike the UAS helpers (newByeRequestUAS,
+// newInDialogRequestUAS, newNotifyRequestUAS) it does not reverse From/To,
+// since we are the same party that sent the original INVITE. The remote
+// target is the 2xx response's Contact; the Route set is the response's
+// Record-Route in reverse order (RFC 3261 section 12.1.2 — the UAC's route
+// set is built by reversing the Record-Route it received).
+func newReferRequestUAC(req *sip.Request, res *sip.Response, cseq uint32, referTo string, replaces string) *sip.Request {
+	cont := res.Contact()
+	refer := sip.NewRequest(sip.REFER, cont.Address)
+
+	from := req.From()
+	to := req.To()
+	callid := req.CallID()
+
+	newFrom := &sip.FromHeader{
+		DisplayName: from.DisplayName,
+		Address:     from.Address,
+		Params:      from.Params,
+	}
+
+	newTo := &sip.ToHeader{
+		DisplayName: to.DisplayName,
+		Address:     to.Address,
+		Params:      to.Params,
+	}
+
+	refer.AppendHeader(newFrom)
+	refer.AppendHeader(newTo)
+	refer.AppendHeader(callid)
+	refer.AppendHeader(&sip.CSeqHeader{SeqNo: cseq, MethodName: sip.REFER})
+	refer.AppendHeader(sip.NewHeader("Refer-To", referTo))
+	if replaces != "" {
+		refer.AppendHeader(sip.NewHeader("Replaces", replaces))
+	}
+
+	appendRouteSet(refer, res.GetHeaders("Record-Route"), true)
+
+	return refer
+}
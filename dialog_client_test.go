@@ -0,0 +1,21 @@
+package sipgo
+
+import "testing"
+
+func TestIsTerminatedState(t *testing.T) {
+	cases := []struct {
+		value string
+		want  bool
+	}{
+		{"terminated;reason=noresource", true},
+		{"terminated", true},
+		{"active;expires=60", false},
+		{"pending", false},
+	}
+
+	for _, c := range cases {
+		if got := isTerminatedState(c.value); got != c.want {
+			t.Errorf("isTerminatedState(%q) = %v, want %v", c.value, got, c.want)
+		}
+	}
+}
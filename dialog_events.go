@@ -0,0 +1,59 @@
+package sipgo
+
+import "github.com/emiago/sipgo/sip"
+
+// DialogEvent is a sealed union of dialog lifecycle events delivered to a
+// DialogServerSession.OnEvent listener, analogous to JAIN-SIP's
+// DialogListener/DialogTerminatedEvent. The concrete types are DialogEarly,
+// DialogConfirmed, DialogTerminated, DialogReInvite, DialogRefer,
+// DialogTimeout and DialogTransportError.
+type DialogEvent interface {
+	dialogEvent()
+}
+
+// DialogEarly is delivered when the dialog is created by a 2xx response sent
+// for the INVITE, before the ACK confirming it has arrived.
+type DialogEarly struct{}
+
+// DialogConfirmed is delivered once ReadAck has matched the ACK for this
+// dialog's 2xx response.
+type DialogConfirmed struct{}
+
+// DialogTerminated is delivered when the dialog ends, whether by BYE, by a
+// non-2xx final response, or by an ACK timeout. Reason is a short,
+// human-readable cause such as "bye", "rejected" or "ack-timeout".
+type DialogTerminated struct {
+	Reason string
+}
+
+// DialogReInvite is delivered when a mid-dialog re-INVITE arrives, alongside
+// the usual return value of DialogServer.ReadInDialogRequest.
+type DialogReInvite struct {
+	Req *sip.Request
+	Tx  sip.ServerTransaction
+}
+
+// DialogRefer is delivered when a REFER arrives and has been accepted by
+// DialogServerSession.ReadRefer.
+type DialogRefer struct {
+	Req *sip.Request
+	Tx  sip.ServerTransaction
+}
+
+// DialogTimeout is delivered alongside DialogTerminated when the dialog ends
+// because no ACK arrived for the 2xx response before AckTimeout elapsed.
+type DialogTimeout struct{}
+
+// DialogTransportError is delivered when the transport fails to send a
+// retransmitted 2xx response.
+type DialogTransportError struct {
+	Err error
+}
+
+func (DialogEarly) dialogEvent()          {}
+func (DialogConfirmed) dialogEvent()      {}
+func (DialogTerminated) dialogEvent()     {}
+func (DialogReInvite) dialogEvent()       {}
+func (DialogRefer) dialogEvent()          {}
+func (DialogTimeout) dialogEvent()        {}
+func (DialogTransportError) dialogEvent() {}
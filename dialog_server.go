@@ -11,20 +11,61 @@ import (
 	"github.com/emiago/sipgo/sip"
 )
 
+// ErrDialogPrackTimeout is returned by RespondReliable when no PRACK arrives
+// for a reliable provisional response before the retransmit timer gives up.
+var ErrDialogPrackTimeout = errors.New("sipgo: no PRACK received for reliable provisional response")
+
+// ErrDialogPrackNoRAck is returned by DialogServer.ReadPrack when the PRACK
+// request is missing the mandatory RAck header.
+var ErrDialogPrackNoRAck = errors.New("sipgo: PRACK missing RAck header")
+
+// ErrDialogCSeqOutOfOrder is returned by DialogServer.ReadInDialogRequest when
+// the request's CSeq is not greater than the last CSeq seen from the remote
+// party for this dialog.
+var ErrDialogCSeqOutOfOrder = errors.New("sipgo: in-dialog request CSeq out of order")
+
+// ErrDialogGlare is returned when a re-INVITE/UPDATE is attempted while
+// another one is already outstanding in either direction for this dialog
+// (RFC 3261 section 14.2). Callers receiving it on the server side should
+// answer the incoming request with 491 Request Pending.
+var ErrDialogGlare = errors.New("sipgo: re-INVITE/UPDATE already in progress on this dialog")
+
+// ErrDialogAckTimeout is surfaced on DialogServerSession.Errors() when no ACK
+// arrives for a 2xx final response before DialogServer.AckTimeout elapses
+// (RFC 3261 section 13.3.1.4). The dialog is terminated with BYE before this
+// is sent.
+var ErrDialogAckTimeout = errors.New("sipgo: no ACK received for 2xx response, dialog terminated")
+
 type DialogServer struct {
-	dialogs    sync.Map // TODO replace with typed version
+	dialogsMu sync.RWMutex
+	dialogs   map[string]*DialogServerSession
+	onDialog  func(*DialogServerSession)
+
 	contactHDR sip.ContactHeader
 	c          *Client
+
+	// AckTimeout bounds how long a DialogServerSession retransmits a 2xx final
+	// response while waiting for the ACK (RFC 3261 section 13.3.1.4). Zero
+	// uses 64*sip.T1, the value mandated by the RFC; tests may lower it.
+	AckTimeout time.Duration
 }
 
 func (s *DialogServer) loadDialog(id string) *DialogServerSession {
-	val, ok := s.dialogs.Load(id)
-	if !ok || val == nil {
-		return nil
-	}
+	s.dialogsMu.RLock()
+	defer s.dialogsMu.RUnlock()
+	return s.dialogs[id]
+}
 
-	t := val.(*DialogServerSession)
-	return t
+func (s *DialogServer) storeDialog(id string, dt *DialogServerSession) {
+	s.dialogsMu.Lock()
+	s.dialogs[id] = dt
+	s.dialogsMu.Unlock()
+}
+
+func (s *DialogServer) deleteDialog(id string) {
+	s.dialogsMu.Lock()
+	delete(s.dialogs, id)
+	s.dialogsMu.Unlock()
 }
 
 // NewDialogServer provides handle for managing UAS dialog
@@ -33,13 +74,22 @@ func (s *DialogServer) loadDialog(id string) *DialogServerSession {
 // In case handling different transports you should have multiple instances per transport
 func NewDialogServer(client *Client, contactHDR sip.ContactHeader) *DialogServer {
 	s := &DialogServer{
-		dialogs:    sync.Map{},
+		dialogs:    make(map[string]*DialogServerSession),
 		contactHDR: contactHDR,
 		c:          client,
 	}
 	return s
 }
 
+// OnDialog registers a listener invoked synchronously, once, for every
+// DialogServerSession returned by ReadInvite. Only the most recently
+// registered listener is kept.
+func (s *DialogServer) OnDialog(fn func(*DialogServerSession)) {
+	s.dialogsMu.Lock()
+	s.onDialog = fn
+	s.dialogsMu.Unlock()
+}
+
 // ReadInvite should read from your OnInvite handler for which it creates dialog context
 // You need to use DialogServerSession for all further responses
 // Do not forget to add ReadAck and ReadBye for confirming dialog and terminating
@@ -54,12 +104,27 @@ func (s *DialogServer) ReadInvite(req *sip.Request, tx sip.ServerTransaction) (*
 		Dialog: Dialog{
 			InviteRequest: req,
 			state:         atomic.Int32{},
-			stateCh:       make(chan sip.DialogState, 3),
-			ctx:           ctx,
-			cancel:        cancel,
+			// stateCh is superseded by OnEvent/Errors below; left nil rather
+			// than allocated dead weight.
+			ctx:    ctx,
+			cancel: cancel,
 		},
-		inviteTx: tx,
-		s:        s,
+		inviteTx:     tx,
+		s:            s,
+		pendingPrack: make(map[uint32]chan *sip.Request),
+		errCh:        make(chan error, 1),
+	}
+
+	if cseq := req.CSeq(); cseq != nil {
+		dtx.remoteCSeq = cseq.SeqNo
+		dtx.localCSeq = cseq.SeqNo
+	}
+
+	s.dialogsMu.RLock()
+	onDialog := s.onDialog
+	s.dialogsMu.RUnlock()
+	if onDialog != nil {
+		onDialog(dtx)
 	}
 
 	return dtx, nil
@@ -82,12 +147,126 @@ func (s *DialogServer) ReadAck(req *sip.Request, tx sip.ServerTransaction) error
 	}
 
 	dt.setState(sip.DialogStateConfirmed)
+	dt.emit(DialogConfirmed{})
 
 	// Acks are normally just absorbed, but in case of proxy
 	// they still need to be passed
 	return nil
 }
 
+// ReadPrack should be read from your OnPrack handler.
+// It finds the dialog by the PRACK's dialog ID, matches the RAck header's
+// RSeq and CSeq against the outstanding provisional from RespondReliable
+// (RFC 3262 section 7.2), cancels the retransmit timer and answers 200 OK.
+func (s *DialogServer) ReadPrack(req *sip.Request, tx sip.ServerTransaction) error {
+	id, err := sip.MakeDialogIDFromRequest(req)
+	if err != nil {
+		return errors.Join(ErrDialogOutsideDialog, err)
+	}
+
+	dt := s.loadDialog(id)
+	if dt == nil {
+		return ErrDialogDoesNotExists
+	}
+
+	rack := req.GetHeader("RAck")
+	if rack == nil {
+		return ErrDialogPrackNoRAck
+	}
+
+	var rseq, cseq uint32
+	var method string
+	if _, err := fmt.Sscanf(rack.Value(), "%d %d %s", &rseq, &cseq, &method); err != nil {
+		return errors.Join(ErrDialogPrackNoRAck, err)
+	}
+
+	// RFC 3262 section 7.2: a PRACK correlates to its provisional response by
+	// RSeq *and* the CSeq (number and method) of the request that provisional
+	// answered, not RSeq alone. Every RespondReliable call on this session
+	// answers s.InviteRequest, so that request's CSeq is the one to match.
+	if !prackMatchesCSeq(dt.InviteRequest.CSeq(), cseq, method) {
+		res := sip.NewResponseFromRequest(req, sip.StatusCallTransactionDoesNotExists, "Call/Transaction Does Not Exist", nil)
+		return tx.Respond(res)
+	}
+
+	dt.mu.Lock()
+	prack, ok := dt.pendingPrack[rseq]
+	if ok {
+		delete(dt.pendingPrack, rseq)
+	}
+	dt.mu.Unlock()
+
+	if !ok {
+		res := sip.NewResponseFromRequest(req, sip.StatusCallTransactionDoesNotExists, "Call/Transaction Does Not Exist", nil)
+		return tx.Respond(res)
+	}
+
+	// Hand the PRACK to the RespondReliable call waiting on it; it owns
+	// stopping the retransmit timer.
+	prack <- req
+
+	res := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil)
+	return tx.Respond(res)
+}
+
+// prackMatchesCSeq reports whether a PRACK's RAck CSeq number and method
+// (RFC 3262 section 7.2) match the provisional response's request, given
+// that request's own CSeq header. It returns false (and so ReadPrack treats
+// the PRACK as unmatched) whenever inviteCSeq is nil.
+func prackMatchesCSeq(inviteCSeq *sip.CSeqHeader, rackCSeq uint32, rackMethod string) bool {
+	if inviteCSeq == nil {
+		return false
+	}
+	return rackCSeq == inviteCSeq.SeqNo && rackMethod == string(inviteCSeq.MethodName)
+}
+
+// ReadInDialogRequest should be read from your OnInvite/OnUpdate handler for
+// a re-INVITE or UPDATE arriving inside an already established dialog.
+// It finds the dialog by MakeDialogIDFromRequest, validates that the CSeq is
+// greater than the last CSeq seen from the remote party, and returns an
+// InDialogSession whose Respond/RespondSDP write back on tx instead of the
+// original INVITE transaction.
+//
+// If a re-INVITE/UPDATE is already outstanding in either direction,
+// ErrDialogGlare is returned (RFC 3261 section 14.2) so the caller can answer
+// with 491 Request Pending.
+func (s *DialogServer) ReadInDialogRequest(req *sip.Request, tx sip.ServerTransaction) (*InDialogSession, error) {
+	id, err := sip.MakeDialogIDFromRequest(req)
+	if err != nil {
+		return nil, errors.Join(ErrDialogOutsideDialog, err)
+	}
+
+	dt := s.loadDialog(id)
+	if dt == nil {
+		return nil, ErrDialogDoesNotExists
+	}
+
+	cseq := req.CSeq()
+	if cseq == nil {
+		return nil, fmt.Errorf("sipgo: in-dialog request missing CSeq")
+	}
+
+	dt.mu.Lock()
+	if cseq.SeqNo <= dt.remoteCSeq {
+		dt.mu.Unlock()
+		return nil, ErrDialogCSeqOutOfOrder
+	}
+	if dt.offerInFlight {
+		dt.mu.Unlock()
+		return nil, ErrDialogGlare
+	}
+
+	dt.remoteCSeq = cseq.SeqNo
+	dt.offerInFlight = true
+	dt.mu.Unlock()
+
+	if req.Method == sip.INVITE {
+		dt.emit(DialogReInvite{Req: req, Tx: tx})
+	}
+
+	return &InDialogSession{DialogServerSession: dt, req: req, tx: tx}, nil
+}
+
 // ReadAck should read from your OnBye handler
 func (s *DialogServer) ReadBye(req *sip.Request, tx sip.ServerTransaction) error {
 	id, err := sip.MakeDialogIDFromRequest(req)
@@ -116,6 +295,7 @@ func (s *DialogServer) ReadBye(req *sip.Request, tx sip.ServerTransaction) error
 	}
 
 	dt.setState(sip.DialogStateEnded)
+	dt.emit(DialogTerminated{Reason: "bye"})
 
 	return nil
 }
@@ -124,11 +304,104 @@ type DialogServerSession struct {
 	Dialog
 	inviteTx sip.ServerTransaction
 	s        *DialogServer
+
+	mu           sync.Mutex
+	rseq         uint32
+	pendingPrack map[uint32]chan *sip.Request
+
+	// remoteCSeq/localCSeq track the last in-dialog CSeq seen from, and sent
+	// to, the remote party. offerInFlight guards against glare (RFC 3261
+	// section 14.2): only one re-INVITE/UPDATE may be outstanding at a time,
+	// in either direction.
+	remoteCSeq    uint32
+	localCSeq     uint32
+	offerInFlight bool
+
+	// errCh surfaces asynchronous dialog errors, currently only the ACK
+	// timeout reported by retransmit2xx; see Errors.
+	errCh chan error
+
+	eventMu sync.Mutex
+	onEvent func(DialogEvent)
+}
+
+// Errors returns a channel on which asynchronous dialog errors are surfaced,
+// currently just ErrDialogAckTimeout from the 2xx retransmit goroutine
+// WriteResponse spawns for final responses.
+func (s *DialogServerSession) Errors() <-chan error {
+	return s.errCh
+}
+
+// OnEvent registers a listener invoked synchronously for every DialogEvent as
+// it happens: state transitions (DialogEarly, DialogConfirmed,
+// DialogTerminated), in-dialog requests (DialogReInvite, DialogRefer), and
+// 2xx-retransmit outcomes (DialogTimeout, DialogTransportError). Only the
+// most recently registered listener is kept. Register before the INVITE is
+// answered to avoid missing the first events.
+func (s *DialogServerSession) OnEvent(fn func(DialogEvent)) {
+	s.eventMu.Lock()
+	s.onEvent = fn
+	s.eventMu.Unlock()
+}
+
+func (s *DialogServerSession) emit(ev DialogEvent) {
+	s.eventMu.Lock()
+	fn := s.onEvent
+	s.eventMu.Unlock()
+	if fn != nil {
+		fn(ev)
+	}
+}
+
+// InDialogSession is a view over an established DialogServerSession for
+// answering a single in-dialog request (re-INVITE or UPDATE) on its own
+// server transaction, as returned by DialogServer.ReadInDialogRequest.
+type InDialogSession struct {
+	*DialogServerSession
+	req *sip.Request
+	tx  sip.ServerTransaction
+}
+
+// Respond answers the in-dialog request on its own transaction.
+func (s *InDialogSession) Respond(statusCode sip.StatusCode, reason string, body []byte, headers ...sip.Header) error {
+	res := sip.NewResponseFromRequest(s.req, statusCode, reason, body)
+	for _, h := range headers {
+		res.AppendHeader(h)
+	}
+	return s.WriteResponse(res)
+}
+
+// RespondSDP is just a wrapper to answer 200 OK with an SDP body.
+func (s *InDialogSession) RespondSDP(sdp []byte) error {
+	if sdp == nil {
+		return fmt.Errorf("sdp not provided")
+	}
+	res := sip.NewSDPResponseFromRequest(s.req, sdp)
+	return s.WriteResponse(res)
+}
+
+// WriteResponse allows passing a custom response for the in-dialog request.
+func (s *InDialogSession) WriteResponse(res *sip.Response) error {
+	res.AppendHeader(&s.s.contactHDR)
+
+	if res.IsSuccess() {
+		s.Dialog.InviteResponse = res
+	}
+
+	err := s.tx.Respond(res)
+
+	if !res.IsProvisional() {
+		s.mu.Lock()
+		s.offerInFlight = false
+		s.mu.Unlock()
+	}
+
+	return err
 }
 
 // Close is always good to call for cleanup or terminating dialog state
 func (s *DialogServerSession) Close() error {
-	s.s.dialogs.Delete(s.ID)
+	s.s.deleteDialog(s.ID)
 	// s.setState(sip.DialogStateEnded)
 	// ctx, _ := context.WithTimeout(context.Background(), transaction.Timer_B)
 	// return s.Bye(ctx)
@@ -151,6 +424,115 @@ func (s *DialogServerSession) Respond(statusCode sip.StatusCode, reason string,
 	return s.WriteResponse(res)
 }
 
+// applyDialogTag ensures every response generated for this session carries
+// the same To-tag (RFC 3261 section 8.2.6.2: "the same tag MUST be used for
+// all responses to that request, both final and provisional"). Since
+// NewResponseFromRequest mints a fresh random To-tag on every call from
+// InviteRequest's (tag-less) To header, the first response's tag is written
+// back onto InviteRequest so later calls reuse it instead of minting a new
+// one.
+func (s *DialogServerSession) applyDialogTag(res *sip.Response) {
+	to := res.To()
+	if to == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reqTo := s.InviteRequest.To()
+	if tag, ok := reqTo.Params["tag"]; ok && tag != "" {
+		to.Params["tag"] = tag
+		return
+	}
+
+	reqTo.Params["tag"] = to.Params["tag"]
+}
+
+// RespondReliable sends a reliable provisional (1xx) response as defined by
+// RFC 3262. It adds Require: 100rel, allocates a fresh RSeq, and retransmits
+// the response with T1/T2 backoff (same cadence as a 2xx retransmit) until the
+// matching PRACK is delivered through DialogServer.ReadPrack, the transaction
+// is canceled/fails, or 64*T1 elapses with no PRACK (ErrDialogPrackTimeout).
+// On success it returns the PRACK request so the caller can inspect its body
+// (e.g. an SDP answer carried in the PRACK).
+func (s *DialogServerSession) RespondReliable(statusCode sip.StatusCode, reason string, body []byte, headers ...sip.Header) (*sip.Request, error) {
+	if statusCode < 100 || statusCode >= 200 {
+		return nil, fmt.Errorf("RespondReliable requires a provisional status code, got %d", statusCode)
+	}
+
+	res := sip.NewResponseFromRequest(s.InviteRequest, statusCode, reason, body)
+	for _, h := range headers {
+		res.AppendHeader(h)
+	}
+
+	rseq := atomic.AddUint32(&s.rseq, 1)
+	res.AppendHeader(sip.NewHeader("Require", "100rel"))
+	res.AppendHeader(sip.NewHeader("RSeq", fmt.Sprintf("%d", rseq)))
+	res.AppendHeader(&s.s.contactHDR)
+	s.applyDialogTag(res)
+	s.Dialog.InviteResponse = res
+
+	// A reliable provisional response assigns the to-tag that starts the
+	// early dialog, so the PRACK (and any other in-dialog request) must be
+	// able to find this session before the final 2xx establishes it.
+	if s.Dialog.ID == "" {
+		if id, err := sip.MakeDialogIDFromResponse(res); err == nil {
+			s.Dialog.ID = id
+			s.s.storeDialog(id, s)
+			s.emit(DialogEarly{})
+		}
+	}
+
+	tx := s.inviteTx
+
+	prack := make(chan *sip.Request, 1)
+	s.mu.Lock()
+	s.pendingPrack[rseq] = prack
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.pendingPrack, rseq)
+		s.mu.Unlock()
+	}()
+
+	if err := tx.Respond(res); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(sip.T1)
+	defer timer.Stop()
+	interval := sip.T1
+	deadline := time.Now().Add(64 * sip.T1)
+
+	for {
+		select {
+		case req := <-prack:
+			return req, nil
+
+		case req := <-tx.Cancels():
+			tx.Respond(sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil))
+			return nil, ErrDialogCanceled
+
+		case <-tx.Done():
+			return nil, tx.Err()
+
+		case <-timer.C:
+			if time.Now().After(deadline) {
+				return nil, ErrDialogPrackTimeout
+			}
+			if err := tx.Respond(res); err != nil {
+				return nil, err
+			}
+			interval *= 2
+			if interval > sip.T2 {
+				interval = sip.T2
+			}
+			timer.Reset(interval)
+		}
+	}
+}
+
 // RespondSDP is just wrapper to call 200 with SDP.
 // It is better to use this when answering as it provide correct headers
 func (s *DialogServerSession) RespondSDP(sdp []byte) error {
@@ -167,6 +549,7 @@ func (s *DialogServerSession) WriteResponse(res *sip.Response) error {
 
 	// Must add contact header
 	res.AppendHeader(&s.s.contactHDR)
+	s.applyDialogTag(res)
 	s.Dialog.InviteResponse = res
 
 	// Do we have cancel in meantime
@@ -191,6 +574,7 @@ func (s *DialogServerSession) WriteResponse(res *sip.Response) error {
 			return err
 		}
 		s.setState(sip.DialogStateEnded)
+		s.emit(DialogTerminated{Reason: fmt.Sprintf("%d %s", res.StatusCode, res.Reason)})
 		return nil
 	}
 
@@ -202,18 +586,80 @@ func (s *DialogServerSession) WriteResponse(res *sip.Response) error {
 	s.Dialog.ID = id
 
 	// We need to make dialog present as ACK can land immediately after
-	s.s.dialogs.Store(id, s)
+	s.s.storeDialog(id, s)
 	s.setState(sip.DialogStateEstablished)
+	// No event here: DialogEarly is reserved for the genuine RFC 3261 early
+	// dialog a reliable provisional response creates (see RespondReliable).
+	// A listener will see DialogConfirmed once ReadAck matches the ACK, or
+	// DialogTerminated/DialogTimeout if none arrives.
 
 	if err := tx.Respond(res); err != nil {
 		// We could also not delete this as Close will handle cleanup
-		s.s.dialogs.Delete(id)
+		s.s.deleteDialog(id)
 		return err
 	}
 
+	// https://datatracker.ietf.org/doc/html/rfc3261#section-13.3.1.4
+	go s.retransmit2xx(res)
+
 	return nil
 }
 
+// retransmit2xx implements the UAS side of RFC 3261 section 13.3.1.4:
+// retransmit a 2xx final response with exponentially increasing intervals
+// starting at T1 and capped at T2, until ReadAck has set the dialog state to
+// Confirmed or DialogServer.AckTimeout elapses. On timeout the dialog is
+// terminated with BYE, moved to Ended, and ErrDialogAckTimeout is surfaced on
+// Errors().
+func (s *DialogServerSession) retransmit2xx(res *sip.Response) {
+	timeout := s.s.AckTimeout
+	if timeout <= 0 {
+		timeout = 64 * sip.T1
+	}
+	deadline := time.Now().Add(timeout)
+
+	interval := sip.T1
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for range timer.C {
+		if sip.DialogState(s.state.Load()) >= sip.DialogStateConfirmed {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			// Bye waits for the dialog to reach Confirmed, or for the INVITE
+			// server transaction to time out, before it will actually send
+			// the BYE (RFC 3261 section 15: a UAS MUST NOT BYE a confirmed
+			// dialog before that). With no ACK that can take up to 64*T1, so
+			// give it that long instead of T1 or the BYE is never sent.
+			ctx, cancel := context.WithTimeout(context.Background(), 64*sip.T1)
+			byeErr := s.Bye(ctx)
+			cancel()
+			s.emit(DialogTimeout{})
+			if byeErr != nil {
+				// Bye's own success path already emits DialogTerminated{"bye"}.
+				s.setState(sip.DialogStateEnded)
+				s.emit(DialogTerminated{Reason: "ack-timeout"})
+			}
+			s.errCh <- errors.Join(ErrDialogAckTimeout, byeErr)
+			return
+		}
+
+		if err := s.inviteTx.Respond(res); err != nil {
+			s.emit(DialogTransportError{Err: err})
+			s.errCh <- err
+			return
+		}
+
+		interval *= 2
+		if interval > sip.T2 {
+			interval = sip.T2
+		}
+		timer.Reset(interval)
+	}
+}
+
 func (s *DialogServerSession) Bye(ctx context.Context) error {
 	state := s.state.Load()
 	// In case dialog terminated
@@ -285,6 +731,7 @@ func (s *DialogServerSession) Bye(ctx context.Context) error {
 			return ErrDialogResponse{res}
 		}
 		s.setState(sip.DialogStateEnded)
+		s.emit(DialogTerminated{Reason: "bye"})
 		return nil
 	case <-tx.Done():
 		return tx.Err()
@@ -293,6 +740,129 @@ func (s *DialogServerSession) Bye(ctx context.Context) error {
 	}
 }
 
+// ReInvite sends a new INVITE inside this dialog carrying a new SDP offer
+// (RFC 3261 section 14.1), reusing the dialog's Route set and remote target
+// and incrementing the local CSeq. If a re-INVITE/UPDATE is already
+// outstanding in either direction, ErrDialogGlare is returned instead of
+// racing requests (RFC 3261 section 14.2).
+func (s *DialogServerSession) ReInvite(ctx context.Context, sdp []byte) (*sip.Response, error) {
+	return s.sendInDialogRequest(ctx, sip.INVITE, sdp)
+}
+
+// Update sends an UPDATE inside this dialog (RFC 3311). Unlike ReInvite it
+// may be used before the dialog is confirmed and carries no implications for
+// the dialog's early/confirmed state.
+func (s *DialogServerSession) Update(ctx context.Context, sdp []byte) (*sip.Response, error) {
+	return s.sendInDialogRequest(ctx, sip.UPDATE, sdp)
+}
+
+func (s *DialogServerSession) sendInDialogRequest(ctx context.Context, method sip.RequestMethod, sdp []byte) (*sip.Response, error) {
+	s.mu.Lock()
+	if s.offerInFlight {
+		s.mu.Unlock()
+		return nil, ErrDialogGlare
+	}
+	s.offerInFlight = true
+	s.localCSeq++
+	cseq := s.localCSeq
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.offerInFlight = false
+		s.mu.Unlock()
+	}()
+
+	req := newInDialogRequestUAS(method, s.Dialog.InviteRequest, s.Dialog.InviteResponse, cseq, sdp)
+
+	// Check Route Header
+	if rr := req.Route(); rr != nil {
+		req.SetDestination(rr.Address.HostPort())
+	}
+
+	tx, err := s.s.c.TransactionRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Terminate() // Terminates current transaction
+
+	select {
+	case res := <-tx.Responses():
+		// 491 Request Pending; the sip package pinned for this baseline has
+		// no sip.StatusRequestPending constant, so compare the literal code.
+		if res.StatusCode == 491 {
+			return res, ErrDialogGlare
+		}
+		if !res.IsSuccess() {
+			return res, ErrDialogResponse{res}
+		}
+		s.Dialog.InviteResponse = res
+		return res, nil
+	case <-tx.Done():
+		return nil, tx.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// newInDialogRequestUAS generates a mid-dialog request (re-INVITE/UPDATE)
+// for UAS, mirroring newByeRequestUAS but with a CSeq and optional SDP body.
+// It does not add a VIA header, as this must be handled by the transport
+// layer.
+func newInDialogRequestUAS(method sip.RequestMethod, req *sip.Request, res *sip.Response, cseq uint32, sdp []byte) *sip.Request {
+	cont := req.Contact()
+	r := sip.NewRequest(method, cont.Address)
+
+	// Reverse from and to
+	from := res.From()
+	to := res.To()
+	callid := res.CallID()
+
+	newFrom := &sip.FromHeader{
+		DisplayName: to.DisplayName,
+		Address:     to.Address,
+		Params:      to.Params,
+	}
+
+	newTo := &sip.ToHeader{
+		DisplayName: from.DisplayName,
+		Address:     from.Address,
+		Params:      from.Params,
+	}
+
+	r.AppendHeader(newFrom)
+	r.AppendHeader(newTo)
+	r.AppendHeader(callid)
+	r.AppendHeader(&sip.CSeqHeader{SeqNo: cseq, MethodName: method})
+	appendRouteSet(r, req.GetHeaders("Record-Route"), false)
+
+	if sdp != nil {
+		r.SetBody(sdp)
+		r.AppendHeader(sip.NewHeader("Content-Type", "application/sdp"))
+	}
+
+	return r
+}
+
+// appendRouteSet builds the Route headers for an in-dialog request from a
+// Record-Route set, in the order each in-dialog helper needs it: forward for
+// a UAS request (newInDialogRequestUAS, newNotifyRequestUAS), since a UA's
+// route set follows the Record-Route of the request that established the
+// dialog in the order received (RFC 3261 section 12.1.1); reversed for a UAC
+// request (newReferRequestUAC), per section 12.1.2. Neither direction is the
+// proxy-forwarding logic of section 16.12.1.2.
+func appendRouteSet(dst *sip.Request, recordRoute []sip.Header, reverse bool) {
+	if !reverse {
+		for _, rr := range recordRoute {
+			dst.AppendHeader(sip.NewHeader("Route", rr.Value()))
+		}
+		return
+	}
+
+	for i := len(recordRoute) - 1; i >= 0; i-- {
+		dst.AppendHeader(sip.NewHeader("Route", recordRoute[i].Value()))
+	}
+}
+
 // newByeRequestUAS generates request for UAS within dialog
 // it does not add VIA header, as this must be handled by transport layer
 func newByeRequestUAS(req *sip.Request, res *sip.Response) *sip.Request {
@@ -0,0 +1,156 @@
+package sipgo
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// waitForRespondCount polls tx's respond count until it reaches n, which also
+// gives the race detector a real happens-before edge (through tx's mutex)
+// between the goroutine that called Respond and this one, instead of relying
+// on a fixed sleep.
+func waitForRespondCount(t *testing.T, tx *fakeServerTransaction, n int, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if tx.respondCount() >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("respond count did not reach %d within %s, got %d", n, timeout, tx.respondCount())
+}
+
+// TestReliableProvisionalThenConfirm drives DialogServer's public surface
+// end to end: an INVITE is read, answered with a reliable 180, the caller's
+// PRACK is delivered back through ReadPrack, the final 200 is sent, and the
+// ACK confirms the dialog. This exercises ReadPrack's RSeq+CSeq matching and
+// RespondReliable's retransmit loop together, not just in isolation.
+func TestReliableProvisionalThenConfirm(t *testing.T) {
+	contact := sip.ContactHeader{Address: sip.Uri{User: "uas", Host: "127.0.0.1", Port: 5060}}
+	s := NewDialogServer(&Client{}, contact)
+
+	invite := sip.NewRequest(sip.INVITE, sip.Uri{User: "uas", Host: "127.0.0.1", Port: 5060})
+	invite.AppendHeader(&sip.FromHeader{Address: sip.Uri{User: "uac", Host: "127.0.0.1"}, Params: sip.HeaderParams{"tag": "fromtag"}})
+	invite.AppendHeader(&sip.ToHeader{Address: sip.Uri{User: "uas", Host: "127.0.0.1"}, Params: sip.NewParams()})
+	callid := sip.CallIDHeader("call-1")
+	invite.AppendHeader(&callid)
+	invite.AppendHeader(&sip.CSeqHeader{SeqNo: 1, MethodName: sip.INVITE})
+	invite.AppendHeader(&sip.ContactHeader{Address: sip.Uri{User: "uac", Host: "127.0.0.1"}})
+
+	tx := newFakeServerTransaction()
+
+	dtx, err := s.ReadInvite(invite, tx)
+	if err != nil {
+		t.Fatalf("ReadInvite: %v", err)
+	}
+
+	prackCh := make(chan *sip.Request, 1)
+	errCh := make(chan error, 1)
+	go func() {
+		req, err := dtx.RespondReliable(180, "Ringing", nil)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		prackCh <- req
+	}()
+
+	// Wait for RespondReliable to send the 180 and register rseq 1 before we
+	// build the PRACK that correlates to it.
+	waitForRespondCount(t, tx, 1, time.Second)
+
+	toTag := invite.To().Params["tag"]
+	if toTag == "" {
+		t.Fatal("RespondReliable did not assign a to-tag")
+	}
+
+	prack := sip.NewRequest(sip.PRACK, sip.Uri{User: "uas", Host: "127.0.0.1"})
+	prack.AppendHeader(&sip.FromHeader{Address: sip.Uri{User: "uac", Host: "127.0.0.1"}, Params: sip.HeaderParams{"tag": "fromtag"}})
+	prack.AppendHeader(&sip.ToHeader{Address: sip.Uri{User: "uas", Host: "127.0.0.1"}, Params: sip.HeaderParams{"tag": toTag}})
+	prack.AppendHeader(&callid)
+	prack.AppendHeader(&sip.CSeqHeader{SeqNo: 2, MethodName: sip.PRACK})
+	prack.AppendHeader(sip.NewHeader("RAck", fmt.Sprintf("%d %d %s", 1, 1, sip.INVITE)))
+
+	prackTx := newFakeServerTransaction()
+	if err := s.ReadPrack(prack, prackTx); err != nil {
+		t.Fatalf("ReadPrack: %v", err)
+	}
+	if n := prackTx.respondCount(); n != 1 || prackTx.responds[0].StatusCode != sip.StatusOK {
+		t.Fatalf("expected PRACK answered 200 OK, got %+v", prackTx.responds)
+	}
+
+	select {
+	case req := <-prackCh:
+		if req != prack {
+			t.Fatal("RespondReliable did not return the delivered PRACK")
+		}
+	case err := <-errCh:
+		t.Fatalf("RespondReliable returned an error instead of the PRACK: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("RespondReliable never unblocked after ReadPrack")
+	}
+
+	if err := dtx.WriteResponse(sip.NewResponseFromRequest(invite, sip.StatusOK, "OK", nil)); err != nil {
+		t.Fatalf("WriteResponse: %v", err)
+	}
+
+	ack := sip.NewRequest(sip.ACK, sip.Uri{User: "uas", Host: "127.0.0.1"})
+	ack.AppendHeader(&sip.FromHeader{Address: sip.Uri{User: "uac", Host: "127.0.0.1"}, Params: sip.HeaderParams{"tag": "fromtag"}})
+	ack.AppendHeader(&sip.ToHeader{Address: sip.Uri{User: "uas", Host: "127.0.0.1"}, Params: sip.HeaderParams{"tag": toTag}})
+	ack.AppendHeader(&callid)
+	ack.AppendHeader(&sip.CSeqHeader{SeqNo: 1, MethodName: sip.ACK})
+
+	if err := s.ReadAck(ack, newFakeServerTransaction()); err != nil {
+		t.Fatalf("ReadAck: %v", err)
+	}
+	if got := sip.DialogState(dtx.state.Load()); got != sip.DialogStateConfirmed {
+		t.Fatalf("dialog state after ReadAck = %v, want Confirmed", got)
+	}
+}
+
+// TestReadPrackRejectsMismatchedCSeq probes the RFC 3262 section 7.2 fix: a
+// PRACK whose RAck CSeq doesn't match the provisional's own request must be
+// rejected with 481, not silently matched on RSeq alone.
+func TestReadPrackRejectsMismatchedCSeq(t *testing.T) {
+	contact := sip.ContactHeader{Address: sip.Uri{User: "uas", Host: "127.0.0.1"}}
+	s := NewDialogServer(&Client{}, contact)
+
+	invite := sip.NewRequest(sip.INVITE, sip.Uri{User: "uas", Host: "127.0.0.1"})
+	invite.AppendHeader(&sip.FromHeader{Address: sip.Uri{User: "uac", Host: "127.0.0.1"}, Params: sip.HeaderParams{"tag": "fromtag"}})
+	invite.AppendHeader(&sip.ToHeader{Address: sip.Uri{User: "uas", Host: "127.0.0.1"}, Params: sip.NewParams()})
+	callid := sip.CallIDHeader("call-2")
+	invite.AppendHeader(&callid)
+	invite.AppendHeader(&sip.CSeqHeader{SeqNo: 5, MethodName: sip.INVITE})
+	invite.AppendHeader(&sip.ContactHeader{Address: sip.Uri{User: "uac", Host: "127.0.0.1"}})
+
+	tx := newFakeServerTransaction()
+	dtx, err := s.ReadInvite(invite, tx)
+	if err != nil {
+		t.Fatalf("ReadInvite: %v", err)
+	}
+
+	go dtx.RespondReliable(180, "Ringing", nil)
+	waitForRespondCount(t, tx, 1, time.Second)
+
+	toTag := invite.To().Params["tag"]
+
+	prack := sip.NewRequest(sip.PRACK, sip.Uri{User: "uas", Host: "127.0.0.1"})
+	prack.AppendHeader(&sip.FromHeader{Address: sip.Uri{User: "uac", Host: "127.0.0.1"}, Params: sip.HeaderParams{"tag": "fromtag"}})
+	prack.AppendHeader(&sip.ToHeader{Address: sip.Uri{User: "uas", Host: "127.0.0.1"}, Params: sip.HeaderParams{"tag": toTag}})
+	prack.AppendHeader(&callid)
+	prack.AppendHeader(&sip.CSeqHeader{SeqNo: 2, MethodName: sip.PRACK})
+	// RSeq 1 is correct, but CSeq 99 doesn't match the INVITE's CSeq of 5.
+	prack.AppendHeader(sip.NewHeader("RAck", fmt.Sprintf("%d %d %s", 1, 99, sip.INVITE)))
+
+	prackTx := newFakeServerTransaction()
+	if err := s.ReadPrack(prack, prackTx); err != nil {
+		t.Fatalf("ReadPrack: %v", err)
+	}
+	if n := prackTx.respondCount(); n != 1 || prackTx.responds[0].StatusCode != sip.StatusCallTransactionDoesNotExists {
+		t.Fatalf("expected mismatched-CSeq PRACK rejected, got %+v", prackTx.responds)
+	}
+}
@@ -0,0 +1,158 @@
+package sipgo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// fakeServerTransaction is a minimal sip.ServerTransaction double.
+type fakeServerTransaction struct {
+	mu       sync.Mutex
+	responds []*sip.Response
+
+	acks    chan *sip.Request
+	cancels chan *sip.Request
+	done    chan struct{}
+	err     error
+}
+
+func newFakeServerTransaction() *fakeServerTransaction {
+	return &fakeServerTransaction{
+		acks:    make(chan *sip.Request),
+		cancels: make(chan *sip.Request),
+		done:    make(chan struct{}),
+	}
+}
+
+func (f *fakeServerTransaction) Respond(res *sip.Response) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responds = append(f.responds, res)
+	return nil
+}
+
+func (f *fakeServerTransaction) Terminate() {}
+
+func (f *fakeServerTransaction) Acks() <-chan *sip.Request { return f.acks }
+
+func (f *fakeServerTransaction) Cancels() <-chan *sip.Request { return f.cancels }
+
+func (f *fakeServerTransaction) Done() <-chan struct{} { return f.done }
+
+func (f *fakeServerTransaction) Err() error { return f.err }
+
+func (f *fakeServerTransaction) respondCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.responds)
+}
+
+func TestAppendRouteSetForward(t *testing.T) {
+	dst := sip.NewRequest(sip.INVITE, sip.Uri{})
+	recordRoute := []sip.Header{
+		sip.NewHeader("Record-Route", "rr1"),
+		sip.NewHeader("Record-Route", "rr2"),
+	}
+
+	appendRouteSet(dst, recordRoute, false)
+
+	routes := dst.GetHeaders("Route")
+	if len(routes) != 2 || routes[0].Value() != "rr1" || routes[1].Value() != "rr2" {
+		t.Fatalf("forward route set out of order, got %v", routes)
+	}
+}
+
+func TestAppendRouteSetReverse(t *testing.T) {
+	dst := sip.NewRequest(sip.INVITE, sip.Uri{})
+	recordRoute := []sip.Header{
+		sip.NewHeader("Record-Route", "rr1"),
+		sip.NewHeader("Record-Route", "rr2"),
+	}
+
+	appendRouteSet(dst, recordRoute, true)
+
+	routes := dst.GetHeaders("Route")
+	if len(routes) != 2 || routes[0].Value() != "rr2" || routes[1].Value() != "rr1" {
+		t.Fatalf("reverse route set out of order, got %v", routes)
+	}
+}
+
+func TestPrackMatchesCSeq(t *testing.T) {
+	cases := []struct {
+		name       string
+		inviteCSeq *sip.CSeqHeader
+		rackCSeq   uint32
+		rackMethod string
+		want       bool
+	}{
+		{"matches", &sip.CSeqHeader{SeqNo: 1, MethodName: sip.INVITE}, 1, "INVITE", true},
+		{"wrong seqno", &sip.CSeqHeader{SeqNo: 1, MethodName: sip.INVITE}, 2, "INVITE", false},
+		{"wrong method", &sip.CSeqHeader{SeqNo: 1, MethodName: sip.INVITE}, 1, "UPDATE", false},
+		{"no invite cseq", nil, 1, "INVITE", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := prackMatchesCSeq(c.inviteCSeq, c.rackCSeq, c.rackMethod); got != c.want {
+				t.Errorf("prackMatchesCSeq(%v, %d, %q) = %v, want %v", c.inviteCSeq, c.rackCSeq, c.rackMethod, got, c.want)
+			}
+		})
+	}
+}
+
+// TestRetransmit2xxRetransmitsBeforeConfirmed exercises the RFC 3261 section
+// 13.3.1.4 retransmit loop: it must re-send the 2xx at least once while the
+// dialog is unconfirmed, and stop without erroring once ReadAck would have
+// moved the dialog to Confirmed. sipgo's INVITE ServerTransaction does accept
+// repeated Respond calls while it is in the 2xx-sent/"Accepted" state (RFC
+// 6026); this only fails if that transaction has already terminated, which
+// is a separate, real error this loop is right to surface as
+// DialogTransportError.
+func TestRetransmit2xxRetransmitsBeforeConfirmed(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tx := newFakeServerTransaction()
+	dtx := &DialogServerSession{
+		Dialog: Dialog{
+			ctx:    ctx,
+			cancel: cancel,
+		},
+		inviteTx: tx,
+		s:        &DialogServer{AckTimeout: 10 * sip.T1},
+		errCh:    make(chan error, 1),
+	}
+
+	res := &sip.Response{}
+
+	done := make(chan struct{})
+	go func() {
+		dtx.retransmit2xx(res)
+		close(done)
+	}()
+
+	// Give the first retransmit tick (fires after T1) time to land before we
+	// simulate the ACK arriving.
+	time.Sleep(sip.T1 + sip.T1/2)
+	if n := tx.respondCount(); n < 1 {
+		t.Fatalf("expected at least one 2xx retransmit before ACK, got %d", n)
+	}
+
+	dtx.setState(sip.DialogStateConfirmed)
+
+	select {
+	case <-done:
+	case <-time.After(2 * sip.T1):
+		t.Fatal("retransmit2xx did not return after the dialog was confirmed")
+	}
+
+	select {
+	case err := <-dtx.errCh:
+		t.Fatalf("unexpected error surfaced after the dialog was confirmed: %v", err)
+	default:
+	}
+}
@@ -0,0 +1,208 @@
+package sipgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// ErrReferNoReferTo is returned by DialogServerSession.ReadRefer when the
+// REFER request is missing the mandatory Refer-To header.
+var ErrReferNoReferTo = errors.New("sipgo: REFER missing Refer-To header")
+
+// ReferSubscriptionExpires is the Expires value reported on the
+// Subscription-State header of NOTIFYs sent while a refer Subscription is
+// still active.
+const ReferSubscriptionExpires = 60 * time.Second
+
+// Subscription is a lightweight RFC 6665 subscription, implicitly created as
+// a side effect of a REFER (RFC 3515 section 2.4.4). It is keyed by the
+// (Call-ID, from-tag, to-tag, event, id) tuple that every NOTIFY belonging to
+// it must carry.
+//
+// The client-side counterpart, DialogClientSession.Refer, sends the REFER and
+// returns a Subscription for reading the NOTIFYs it gets back; see
+// dialog_client.go.
+type Subscription struct {
+	id    string
+	event string
+
+	c          *Client
+	contactHDR sip.ContactHeader
+
+	// session is set when this Subscription was created by
+	// DialogServerSession.ReadRefer, i.e. we are the REFER's recipient and
+	// therefore own sending its NOTIFYs. Notify shares session's localCSeq
+	// counter instead of keeping an independent one, since RFC 3261 section
+	// 12.2.1.1 requires a single, strictly increasing CSeq series per
+	// direction per dialog and the UAS may also send re-INVITE/UPDATE/BYE on
+	// the same dialog.
+	session *DialogServerSession
+
+	referReq *sip.Request
+	referRes *sip.Response
+
+	mu     sync.Mutex
+	active bool
+}
+
+// SubscriptionID builds the (Call-ID, from-tag, to-tag, event, id) tuple that
+// correlates a REFER to the NOTIFYs of its implicit subscription.
+func SubscriptionID(callID, fromTag, toTag, event, id string) string {
+	return fmt.Sprintf("%s;%s;%s;%s;%s", callID, fromTag, toTag, event, id)
+}
+
+// ID returns this subscription's (Call-ID, from-tag, to-tag, event, id) key.
+func (s *Subscription) ID() string {
+	return s.id
+}
+
+// ReadRefer should be read from your OnRefer handler. It validates the
+// Refer-To header, answers 202 Accepted, and returns a Subscription for the
+// implicit NOTIFY subscription RFC 3515 section 2.4.4 requires, keyed by
+// (Call-ID, from-tag, to-tag, event="refer", id=CSeq).
+func (s *DialogServerSession) ReadRefer(req *sip.Request, tx sip.ServerTransaction) (*Subscription, error) {
+	referTo := req.GetHeader("Refer-To")
+	if referTo == nil || referTo.Value() == "" {
+		return nil, ErrReferNoReferTo
+	}
+
+	cseq := req.CSeq()
+	if cseq == nil {
+		return nil, fmt.Errorf("sipgo: REFER missing CSeq")
+	}
+
+	callid := req.CallID()
+	from := req.From()
+	to := req.To()
+
+	sub := &Subscription{
+		id:         SubscriptionID(callid.Value(), from.Params["tag"], to.Params["tag"], "refer", fmt.Sprintf("%d", cseq.SeqNo)),
+		event:      "refer",
+		c:          s.s.c,
+		contactHDR: s.s.contactHDR,
+		session:    s,
+		referReq:   req,
+	}
+
+	res := sip.NewResponseFromRequest(req, 202, "Accepted", nil)
+	res.AppendHeader(&s.s.contactHDR)
+	if err := tx.Respond(res); err != nil {
+		return nil, err
+	}
+	sub.referRes = res
+	sub.active = true
+
+	s.emit(DialogRefer{Req: req, Tx: tx})
+
+	return sub, nil
+}
+
+// Notify sends a NOTIFY carrying a message/sipfrag body reporting the
+// progress of the referred request, e.g.
+//
+//	sub.Notify("active", []byte("SIP/2.0 100 Trying"))
+//	sub.Notify("terminated", []byte("SIP/2.0 200 OK"))
+//
+// Passing state "terminated" ends the subscription and reports
+// Subscription-State: terminated;reason=noresource; any other state keeps it
+// active with Subscription-State: active;expires=....
+func (s *Subscription) Notify(state string, sipfrag []byte) error {
+	if s.session == nil {
+		return fmt.Errorf("sipgo: Notify can only be called on the REFER recipient's Subscription")
+	}
+
+	s.mu.Lock()
+	if !s.active {
+		s.mu.Unlock()
+		return fmt.Errorf("sipgo: subscription %q already terminated", s.id)
+	}
+	s.mu.Unlock()
+
+	s.session.mu.Lock()
+	s.session.localCSeq++
+	cseq := s.session.localCSeq
+	s.session.mu.Unlock()
+
+	req := newNotifyRequestUAS(s.referReq, s.referRes, cseq, s.event, state, sipfrag)
+	req.AppendHeader(&s.contactHDR)
+
+	if rr := req.Route(); rr != nil {
+		req.SetDestination(rr.Address.HostPort())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 64*sip.T1)
+	defer cancel()
+
+	tx, err := s.c.TransactionRequest(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer tx.Terminate()
+
+	select {
+	case res := <-tx.Responses():
+		if !res.IsSuccess() {
+			return ErrDialogResponse{res}
+		}
+	case <-tx.Done():
+		return tx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	s.active = state != "terminated"
+	s.mu.Unlock()
+
+	return nil
+}
+
+// newNotifyRequestUAS generates a NOTIFY request for the subscription
+// implicitly created by a REFER. It mirrors newByeRequestUAS's from/to/route
+// handling and does not add a VIA header, as this must be handled by the
+// transport layer.
+func newNotifyRequestUAS(req *sip.Request, res *sip.Response, cseq uint32, event, state string, sipfrag []byte) *sip.Request {
+	cont := req.Contact()
+	notify := sip.NewRequest(sip.NOTIFY, cont.Address)
+
+	// Reverse from and to
+	from := res.From()
+	to := res.To()
+	callid := res.CallID()
+
+	newFrom := &sip.FromHeader{
+		DisplayName: to.DisplayName,
+		Address:     to.Address,
+		Params:      to.Params,
+	}
+
+	newTo := &sip.ToHeader{
+		DisplayName: from.DisplayName,
+		Address:     from.Address,
+		Params:      from.Params,
+	}
+
+	notify.AppendHeader(newFrom)
+	notify.AppendHeader(newTo)
+	notify.AppendHeader(callid)
+	notify.AppendHeader(&sip.CSeqHeader{SeqNo: cseq, MethodName: sip.NOTIFY})
+	notify.AppendHeader(sip.NewHeader("Event", event))
+
+	subState := fmt.Sprintf("active;expires=%d", int(ReferSubscriptionExpires.Seconds()))
+	if state == "terminated" {
+		subState = "terminated;reason=noresource"
+	}
+	notify.AppendHeader(sip.NewHeader("Subscription-State", subState))
+
+	notify.AppendHeader(sip.NewHeader("Content-Type", "message/sipfrag"))
+	notify.SetBody(sipfrag)
+
+	appendRouteSet(notify, req.GetHeaders("Record-Route"), false)
+
+	return notify
+}
@@ -0,0 +1,11 @@
+package sipgo
+
+import "testing"
+
+func TestSubscriptionID(t *testing.T) {
+	got := SubscriptionID("call-1", "from-tag", "to-tag", "refer", "1")
+	want := "call-1;from-tag;to-tag;refer;1"
+	if got != want {
+		t.Errorf("SubscriptionID(...) = %q, want %q", got, want)
+	}
+}